@@ -0,0 +1,136 @@
+// +build go1.9
+
+// Copyright 2017 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ora
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"database/sql/driver"
+	"io"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// lobReader streams a fetched LOB's contents without materializing it,
+// used when the statement's LobAsReader option is set. It takes its own
+// reference on dpiLob, released on Close.
+type lobReader struct {
+	conn   *conn
+	dpiLob *C.dpiLob
+	offset C.uint64_t
+	closed bool
+}
+
+// Read implements io.Reader, advancing the LOB's read offset on each call.
+func (l *lobReader) Read(p []byte) (int, error) {
+	if l.closed {
+		return 0, errors.New("Read of closed Lob")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := C.uint64_t(len(p))
+	if C.dpiLob_readBytes(l.dpiLob, l.offset+1, n, (*C.char)(unsafe.Pointer(&p[0])), &n) == C.DPI_FAILURE {
+		return 0, l.conn.getError()
+	}
+	l.offset += n
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return int(n), nil
+}
+
+// Close releases the dpiLob reference taken for this reader.
+func (l *lobReader) Close() error {
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if C.dpiLob_release(l.dpiLob) == C.DPI_FAILURE {
+		return l.conn.getError()
+	}
+	return nil
+}
+
+// LobWriter streams data into a temporary LOB in chunks, keeping the LOB
+// resource open across writes (dpiLob_openResource/closeResource) instead
+// of paying a round trip per dpiLob_writeBytes call, so multi-GB CLOBs/
+// BLOBs can be written without buffering the whole value in memory.
+type LobWriter struct {
+	conn   *conn
+	dpiLob *C.dpiLob
+	offset C.uint64_t
+	opened bool
+}
+
+// NewLobWriter creates a temporary BLOB (or CLOB, if isClob) on dc - the
+// driver.Conn obtained from (*sql.Conn).Raw on a *sql.DB opened with this
+// driver - and returns a writer streaming into it. The returned LobWriter
+// must be Closed. A *LobWriter is not accepted as an Exec or Query
+// argument: it is neither a Lob nor an io.Reader.
+func NewLobWriter(dc driver.Conn, isClob bool) (*LobWriter, error) {
+	c, ok := dc.(*conn)
+	if !ok {
+		return nil, errors.Errorf("NewLobWriter: %T is not an ora connection", dc)
+	}
+	typ := C.dpiOracleTypeNum(C.DPI_ORACLE_TYPE_BLOB)
+	if isClob {
+		typ = C.DPI_ORACLE_TYPE_CLOB
+	}
+	var lob *C.dpiLob
+	if C.dpiConn_newTempLob(c.dpiConn, typ, &lob) == C.DPI_FAILURE {
+		return nil, c.getError()
+	}
+	return newLobWriter(c, lob)
+}
+
+// newLobWriter opens lob's LOB resource and returns a writer streaming
+// into it from the start. The caller owns lob's lifetime/reference.
+func newLobWriter(c *conn, lob *C.dpiLob) (*LobWriter, error) {
+	if C.dpiLob_openResource(lob) == C.DPI_FAILURE {
+		return nil, c.getError()
+	}
+	return &LobWriter{conn: c, dpiLob: lob, opened: true}, nil
+}
+
+// Write implements io.Writer.
+func (w *LobWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if C.dpiLob_writeBytes(w.dpiLob, w.offset+1, (*C.char)(unsafe.Pointer(&p[0])), C.uint64_t(len(p))) == C.DPI_FAILURE {
+		return 0, w.conn.getError()
+	}
+	w.offset += C.uint64_t(len(p))
+	return len(p), nil
+}
+
+// Close closes the LOB resource opened by newLobWriter.
+func (w *LobWriter) Close() error {
+	if !w.opened {
+		return nil
+	}
+	w.opened = false
+	if C.dpiLob_closeResource(w.dpiLob) == C.DPI_FAILURE {
+		return w.conn.getError()
+	}
+	return nil
+}
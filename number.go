@@ -0,0 +1,65 @@
+// +build go1.9
+
+// Copyright 2017 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ora
+
+import (
+	"database/sql/driver"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Number holds an Oracle NUMBER in its textual representation, so that
+// values wider than int64/float64 (e.g. NUMBER(38)) round-trip without
+// the precision loss a float64 conversion would cause.
+type Number string
+
+// Scan implements sql.Scanner.
+func (n *Number) Scan(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		*n = ""
+	case string:
+		*n = Number(x)
+	case []byte:
+		*n = Number(x)
+	case Number:
+		*n = x
+	default:
+		return errors.Errorf("Number.Scan: unsupported type %T", v)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n Number) Value() (driver.Value, error) {
+	return string(n), nil
+}
+
+// Rat parses n as a big.Rat, reporting false if it isn't a valid number.
+func (n Number) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(n))
+}
+
+// Int parses n as a big.Int, reporting false if it isn't a valid integer.
+func (n Number) Int() (*big.Int, bool) {
+	return new(big.Int).SetString(string(n), 10)
+}
+
+// String implements fmt.Stringer.
+func (n Number) String() string { return string(n) }
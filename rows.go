@@ -0,0 +1,350 @@
+// +build go1.9
+
+// Copyright 2017 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ora
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// oracleTypeNames maps the oracle types this driver fetches to the name
+// database/sql's ColumnType reports for ColumnTypeDatabaseTypeName.
+var oracleTypeNames = map[C.dpiOracleTypeNum]string{
+	C.DPI_ORACLE_TYPE_VARCHAR:      "VARCHAR2",
+	C.DPI_ORACLE_TYPE_NVARCHAR:     "NVARCHAR2",
+	C.DPI_ORACLE_TYPE_CHAR:         "CHAR",
+	C.DPI_ORACLE_TYPE_NCHAR:        "NCHAR",
+	C.DPI_ORACLE_TYPE_RAW:          "RAW",
+	C.DPI_ORACLE_TYPE_DATE:         "DATE",
+	C.DPI_ORACLE_TYPE_TIMESTAMP:    "TIMESTAMP",
+	C.DPI_ORACLE_TYPE_TIMESTAMP_TZ: "TIMESTAMP WITH TIME ZONE",
+	C.DPI_ORACLE_TYPE_BOOLEAN:      "BOOLEAN",
+	C.DPI_ORACLE_TYPE_CLOB:         "CLOB",
+	C.DPI_ORACLE_TYPE_BLOB:         "BLOB",
+	C.DPI_ORACLE_TYPE_STMT:         "CURSOR",
+}
+
+var _ = driver.Rows((*rows)(nil))
+var _ = driver.RowsNextResultSet((*rows)(nil))
+var _ = driver.RowsColumnTypeDatabaseTypeName((*rows)(nil))
+
+// rows is the result of a query. It fetches rows in batches of
+// fetchRowCount and additionally knows how to move to the next implicit
+// result set, be it a DBMS_SQL.RETURN_RESULT cursor or a REF CURSOR bound
+// through a RefCursor OUT parameter.
+type rows struct {
+	*statement
+	dpiStmt *C.dpiStmt
+	columns []Column
+	vars    []*C.dpiVar
+	data    [][]C.dpiData
+
+	arraySize   C.uint32_t
+	bufferStart C.uint32_t
+	bufferSize  C.uint32_t
+	pos         C.uint32_t
+
+	// pending holds child cursors (REF CURSOR OUT binds or
+	// DBMS_SQL.RETURN_RESULT result sets) not yet switched to.
+	pending []*C.dpiStmt
+	closed  bool
+}
+
+// Columns returns the names of the columns.
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, c := range r.columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName returns the Oracle type name of the i-th
+// column, rendering NUMBER columns as NUMBER(p,s) so that database/sql's
+// ColumnType reports the real precision/scale instead of a bare "NUMBER".
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	col := r.columns[i]
+	if col.OracleType == C.DPI_ORACLE_TYPE_NUMBER {
+		// Oracle reports plain, unconstrained NUMBER columns as
+		// precision=0, scale=-127, not scale=0 - without this case
+		// they'd render as the bogus "NUMBER(0,-127)".
+		if col.Scale == -127 || (col.Scale == 0 && col.Precision == 0) {
+			return "NUMBER"
+		}
+		return fmt.Sprintf("NUMBER(%d,%d)", col.Precision, col.Scale)
+	}
+	return oracleTypeNames[col.OracleType]
+}
+
+// Close closes the rows iterator and releases the held statement handle(s).
+func (r *rows) Close() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.close()
+}
+
+func (r *rows) close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.releaseColumns()
+	for _, stmt := range r.pending {
+		C.dpiStmt_release(stmt)
+	}
+	r.pending = nil
+	return err
+}
+
+// releaseColumns releases the dpiVars and the dpiStmt reference taken by
+// setupColumns, in preparation for either Close or a switch to the next
+// implicit result set.
+func (r *rows) releaseColumns() error {
+	for _, v := range r.vars {
+		if v != nil {
+			C.dpiVar_release(v)
+		}
+	}
+	r.vars, r.data, r.columns = nil, nil, nil
+	var err error
+	if r.dpiStmt != nil {
+		if C.dpiStmt_release(r.dpiStmt) == C.DPI_FAILURE {
+			err = r.getError()
+		}
+		r.dpiStmt = nil
+	}
+	return err
+}
+
+// numberNativeType picks the native type to fetch a NUMBER column as:
+// the statement's explicit NumberAs choice if any, else a heuristic from
+// the column's precision/scale: scale 0 with precision up to 18 as int64,
+// any positive scale as float64 (which may round values with many
+// significant digits), and everything else as its textual representation.
+func (r *rows) numberNativeType(precision C.int16_t, scale C.int8_t) C.dpiNativeTypeNum {
+	switch r.NumberAs {
+	case NumberAsInt64:
+		return C.DPI_NATIVE_TYPE_INT64
+	case NumberAsFloat64:
+		return C.DPI_NATIVE_TYPE_DOUBLE
+	case NumberAsString:
+		return C.DPI_NATIVE_TYPE_BYTES
+	}
+	switch {
+	case scale == 0 && precision <= 18:
+		return C.DPI_NATIVE_TYPE_INT64
+	case scale > 0:
+		return C.DPI_NATIVE_TYPE_DOUBLE
+	default:
+		return C.DPI_NATIVE_TYPE_BYTES
+	}
+}
+
+// setupColumns defines the output variables for dpiStmt and makes r fetch
+// from it, taking its own reference on dpiStmt.
+func (r *rows) setupColumns(dpiStmt *C.dpiStmt, colCount int) error {
+	arraySize := C.uint32_t(fetchRowCount)
+	if r.fetchRowCount > 0 {
+		arraySize = C.uint32_t(r.fetchRowCount)
+	}
+	r.arraySize = arraySize
+	C.dpiStmt_setFetchArraySize(dpiStmt, arraySize)
+
+	r.dpiStmt = dpiStmt
+	r.columns = make([]Column, colCount)
+	r.vars = make([]*C.dpiVar, colCount)
+	r.data = make([][]C.dpiData, colCount)
+	r.bufferStart, r.bufferSize, r.pos = 0, 0, 0
+
+	var info C.dpiQueryInfo
+	for i := 0; i < colCount; i++ {
+		if C.dpiStmt_getQueryInfo(dpiStmt, C.uint32_t(i+1), &info) == C.DPI_FAILURE {
+			return r.getError()
+		}
+		bufSize := int(info.clientSizeInBytes)
+		switch info.oracleTypeNum {
+		case C.DPI_ORACLE_TYPE_NUMBER:
+			info.defaultNativeTypeNum = r.numberNativeType(info.precision, info.scale)
+		case C.DPI_ORACLE_TYPE_DATE:
+			info.defaultNativeTypeNum = C.DPI_NATIVE_TYPE_TIMESTAMP
+		}
+		r.columns[i] = Column{
+			Name:       C.GoStringN(info.name, C.int(info.nameLength)),
+			OracleType: info.oracleTypeNum,
+			NativeType: info.defaultNativeTypeNum,
+			Size:       info.clientSizeInBytes,
+			Precision:  info.precision,
+			Scale:      info.scale,
+			Nullable:   info.nullOk == 1,
+			ObjectType: info.objectType,
+		}
+		switch info.oracleTypeNum {
+		case C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_ORACLE_TYPE_NVARCHAR, C.DPI_ORACLE_TYPE_CHAR, C.DPI_ORACLE_TYPE_NCHAR:
+			bufSize *= 4
+		}
+		var err error
+		if r.vars[i], r.data[i], err = r.statement.newVar(
+			false, info.oracleTypeNum, info.defaultNativeTypeNum, int(arraySize), bufSize,
+		); err != nil {
+			return err
+		}
+		if C.dpiStmt_define(dpiStmt, C.uint32_t(i+1), r.vars[i]) == C.DPI_FAILURE {
+			return r.getError()
+		}
+	}
+	if C.dpiStmt_addRef(dpiStmt) == C.DPI_FAILURE {
+		return r.getError()
+	}
+	return nil
+}
+
+// Next is called to populate the next row of data into the provided slice.
+func (r *rows) Next(dest []driver.Value) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.pos >= r.bufferSize {
+		var bufferRowIndex, rowsFetched C.uint32_t
+		var moreRows C.int
+		if C.dpiStmt_fetchRows(r.dpiStmt, r.arraySize, &bufferRowIndex, &rowsFetched, &moreRows) == C.DPI_FAILURE {
+			return r.getError()
+		}
+		if rowsFetched == 0 {
+			return io.EOF
+		}
+		r.bufferStart, r.bufferSize, r.pos = bufferRowIndex, rowsFetched, 0
+	}
+	idx := r.bufferStart + r.pos
+	for i := range r.columns {
+		v, err := r.columnValue(i, &r.data[i][idx])
+		if err != nil {
+			return errors.Wrapf(err, "column %q", r.columns[i].Name)
+		}
+		dest[i] = v
+	}
+	r.pos++
+	return nil
+}
+
+// HasNextResultSet reports whether calling NextResultSet will succeed, be
+// it a queued REF CURSOR OUT bind or the next DBMS_SQL.RETURN_RESULT cursor.
+func (r *rows) HasNextResultSet() bool {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.pending) > 0 {
+		return true
+	}
+	var child *C.dpiStmt
+	if C.dpiStmt_getImplicitResult(r.dpiStmt, &child) == C.DPI_FAILURE || child == nil {
+		return false
+	}
+	r.pending = append(r.pending, child)
+	return true
+}
+
+// NextResultSet switches r to fetch from the next implicit result set.
+func (r *rows) NextResultSet() error {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.pending) == 0 {
+		var child *C.dpiStmt
+		if C.dpiStmt_getImplicitResult(r.dpiStmt, &child) == C.DPI_FAILURE || child == nil {
+			return io.EOF
+		}
+		r.pending = append(r.pending, child)
+	}
+	next := r.pending[0]
+	r.pending = r.pending[1:]
+
+	if err := r.releaseColumns(); err != nil {
+		C.dpiStmt_release(next)
+		return err
+	}
+	var colCount C.uint32_t
+	if C.dpiStmt_getNumQueryColumns(next, &colCount) == C.DPI_FAILURE {
+		C.dpiStmt_release(next)
+		return r.getError()
+	}
+	return r.setupColumns(next, int(colCount))
+}
+
+// columnValue converts the i-th column's data at the current row into a
+// driver.Value.
+func (r *rows) columnValue(i int, d *C.dpiData) (driver.Value, error) {
+	if d.isNull == 1 {
+		return nil, nil
+	}
+	col := r.columns[i]
+	switch col.NativeType {
+	case C.DPI_NATIVE_TYPE_BYTES:
+		b := C.dpiData_getBytes(d)
+		p := C.GoBytes(unsafe.Pointer(b.ptr), C.int(b.length))
+		if col.OracleType == C.DPI_ORACLE_TYPE_NUMBER {
+			return Number(p), nil
+		}
+		return p, nil
+	case C.DPI_NATIVE_TYPE_INT64:
+		return int64(C.dpiData_getInt64(d)), nil
+	case C.DPI_NATIVE_TYPE_UINT64:
+		return uint64(C.dpiData_getUint64(d)), nil
+	case C.DPI_NATIVE_TYPE_FLOAT:
+		return float64(C.dpiData_getFloat(d)), nil
+	case C.DPI_NATIVE_TYPE_DOUBLE:
+		return float64(C.dpiData_getDouble(d)), nil
+	case C.DPI_NATIVE_TYPE_BOOLEAN:
+		return C.dpiData_getBool(d) == 1, nil
+	case C.DPI_NATIVE_TYPE_TIMESTAMP:
+		ts := C.dpiData_getTimestamp(d)
+		return time.Date(
+			int(ts.year), time.Month(ts.month), int(ts.day),
+			int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
+			time.FixedZone("", int(ts.tzHourOffset)*3600+int(ts.tzMinuteOffset)*60),
+		), nil
+	case C.DPI_NATIVE_TYPE_LOB:
+		lob := C.dpiData_getLOB(d)
+		if r.LobAsReader {
+			if C.dpiLob_addRef(lob) == C.DPI_FAILURE {
+				return nil, r.getError()
+			}
+			return &lobReader{conn: r.statement.conn, dpiLob: lob}, nil
+		}
+		var lobLen C.uint64_t
+		if C.dpiLob_getSize(lob, &lobLen) == C.DPI_FAILURE {
+			return nil, r.getError()
+		}
+		buf := make([]byte, lobLen)
+		if lobLen > 0 {
+			n := lobLen
+			if C.dpiLob_readBytes(lob, 1, lobLen, (*C.char)(unsafe.Pointer(&buf[0])), &n) == C.DPI_FAILURE {
+				return nil, r.getError()
+			}
+			buf = buf[:n]
+		}
+		return buf, nil
+	default:
+		return nil, errors.Errorf("unsupported native type %d", col.NativeType)
+	}
+}